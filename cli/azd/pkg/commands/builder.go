@@ -2,27 +2,32 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"runtime/debug"
+	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/identity"
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/terraform"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/iostreams"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 
-	"github.com/mattn/go-colorable"
-	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // BuildOptions contains the optional parameters for the Build function.
@@ -38,6 +43,50 @@ type BuildOptions struct {
 	DisableCmdUsageEvent bool
 }
 
+// flagAuthMethod is the name of the persistent flag used to force a single
+// credential method instead of probing the default chain. See identity.AuthMethod.
+const flagAuthMethod = "auth-method"
+
+// defaultAuthMethod returns the --auth-method default, honoring AZD_AUTH_METHOD when
+// the flag itself is not set.
+func defaultAuthMethod() string {
+	if method := os.Getenv(identity.EnvVarAuthMethod); method != "" {
+		return method
+	}
+
+	return string(identity.AuthMethodAuto)
+}
+
+// flagDebug is the name of the persistent flag that raises the human/JSON log
+// output to debug level. Debug-level entries are always captured in the on-disk
+// debug log regardless of this flag.
+const flagDebug = "debug"
+
+// envVarDebug mirrors flagDebug so users can set it for an entire shell session.
+const envVarDebug = "AZD_DEBUG"
+
+func defaultDebug() bool {
+	return os.Getenv(envVarDebug) != ""
+}
+
+// flagHeader and flagHeaderCommand let users attach auth headers (short-lived
+// tokens for a corporate proxy or zero-trust gateway) to every Azure API call azd
+// makes, without patching the binary. See httputil.HeaderTransport.
+const (
+	flagHeader        = "header"
+	flagHeaderCommand = "header-command"
+)
+
+// parseHeaderFlag parses a single "Key=Value" --header flag value.
+func parseHeaderFlag(value string) (key, headerValue string, err error) {
+	key, headerValue, found := strings.Cut(value, "=")
+	if !found {
+		return "", "", fmt.Errorf("invalid --header %q, expected Key=Value", value)
+	}
+
+	return key, headerValue, nil
+}
+
 // Build builds a Cobra command, attaching an action.
 //
 // All commands should be built with this command builder vs manually instantiating cobra commands.
@@ -70,23 +119,70 @@ func Build(
 		Long:    buildOptions.Long,
 		Aliases: buildOptions.Aliases,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx, azdCtx, err := createRootContext(cmd.Context(), cmd, rootOptions)
-			if err != nil {
-				return err
-			}
+			// The whole body, not just action.Run, is wrapped in panic recovery:
+			// signalContext, createRootContext, and maybeCheckForNewVersion all run before
+			// any telemetry span exists, so a panic there would otherwise still crash the
+			// process instead of being recovered.
+			return runWithPanicRecovery(cmd.Context(), nil, func(outerCtx context.Context) error {
+				sigCtx, stop := signalContext(outerCtx)
+				defer stop()
 
-			runCmd := func(cmdCtx context.Context) error {
-				return action.Run(cmdCtx, cmd, args, azdCtx)
-			}
+				ctx, azdCtx, err := createRootContext(sigCtx, cmd, rootOptions)
+				if err != nil {
+					return err
+				}
+
+				maybeCheckForNewVersion(ctx, cmd, internal.Version)
+
+				runCmd := func(cmdCtx context.Context) error {
+					return action.Run(cmdCtx, cmd, args, azdCtx)
+				}
+
+				if buildOptions.DisableCmdUsageEvent {
+					return runCmd(ctx)
+				}
 
-			if buildOptions.DisableCmdUsageEvent {
-				return runCmd(ctx)
-			} else {
 				return runCmdWithTelemetry(ctx, cmd, runCmd)
-			}
+			})
 		},
 	}
 	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
+	cmd.PersistentFlags().Bool(
+		flagDebug,
+		defaultDebug(),
+		fmt.Sprintf("Enables debug logging to stderr; the debug log under ~/.azd/logs always includes it "+
+			"(or set %s).", envVarDebug),
+	)
+	cmd.PersistentFlags().Bool(
+		flagNoVersionWarning,
+		defaultNoVersionWarning(),
+		fmt.Sprintf("Disables the startup check for a newer azd release (or set %s).", envVarNoVersionWarning),
+	)
+	cmd.PersistentFlags().String(
+		flagAuthMethod,
+		defaultAuthMethod(),
+		fmt.Sprintf(
+			"Forces a single authentication method instead of probing the default chain "+
+				"(one of: %s, %s, %s, %s, %s, %s).",
+			identity.AuthMethodAuto,
+			identity.AuthMethodEnvironment,
+			identity.AuthMethodWorkloadIdentity,
+			identity.AuthMethodManagedIdentity,
+			identity.AuthMethodDeviceCode,
+			identity.AuthMethodAzureCLI,
+		),
+	)
+	cmd.PersistentFlags().StringArray(
+		flagHeader,
+		nil,
+		"Attaches a static 'Key=Value' header to every Azure API request azd makes. Can be repeated.",
+	)
+	cmd.PersistentFlags().String(
+		flagHeaderCommand,
+		"",
+		"Runs the given command before every Azure API request azd makes, parsing its stdout as "+
+			"'Key: Value' lines and attaching them as headers.",
+	)
 	action.SetupFlags(
 		cmd.PersistentFlags(),
 		cmd.Flags(),
@@ -100,14 +196,46 @@ func runCmdWithTelemetry(ctx context.Context, cmd *cobra.Command, runCmd func(ct
 	spanCtx, span := telemetry.GetTracer().Start(ctx, events.GetCommandEventName(cmd.CommandPath()))
 	defer span.End()
 
-	err := runCmd(spanCtx)
+	err := runWithPanicRecovery(spanCtx, span, runCmd)
 	if err != nil {
-		span.SetStatus(codes.Error, "UnknownError")
+		if errors.Is(err, context.Canceled) {
+			span.SetStatus(codes.Error, "Canceled")
+		} else {
+			span.SetStatus(codes.Error, "UnknownError")
+		}
 	}
 
 	return err
 }
 
+// runWithPanicRecovery runs runCmd, recovering from and logging any panic instead
+// of letting it crash the whole process. Build uses this twice: once around the
+// entire RunE body (span nil, since no telemetry span exists yet), and again around
+// just action.Run via runCmdWithTelemetry, where a recovered panic is also recorded
+// on the given span. The outer layer is a pure backstop for setup code
+// (signalContext, createRootContext, maybeCheckForNewVersion) that runs before a
+// span exists; a panic in action.Run is caught by the inner layer first, except when
+// DisableCmdUsageEvent is set, where only the outer layer runs.
+func runWithPanicRecovery(
+	ctx context.Context,
+	span trace.Span,
+	runCmd func(ctx context.Context) error,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			logging.GetLogger(ctx).Error("panic recovered: %v\n%s", r, stack)
+			if span != nil {
+				span.RecordError(fmt.Errorf("panic: %v", r))
+				span.SetStatus(codes.Error, "UnknownError")
+			}
+			err = fmt.Errorf("azd encountered an unexpected error and has recovered: %v", r)
+		}
+	}()
+
+	return runCmd(ctx)
+}
+
 // Create the core context for use in all Azd commands
 // Registers context values for azCli, formatter, writer, console and more.
 func createRootContext(
@@ -128,18 +256,38 @@ func createRootContext(
 	runner := exec.NewCommandRunner(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
 	ctx = exec.WithCommandRunner(ctx, runner)
 
+	headerTransport, err := headerTransportFromFlags(cmd)
+	if err != nil {
+		return ctx, nil, err
+	}
+
 	azCliArgs := azcli.NewAzCliArgs{
 		EnableDebug:     rootOptions.EnableDebugLogging,
 		EnableTelemetry: rootOptions.EnableTelemetry,
 		CommandRunner:   runner,
 	}
+	if headerTransport != nil {
+		azCliArgs.Transport = headerTransport
+	}
 
 	// Set default credentials used for operations against azure data/control planes
-	credentials, err := azidentity.NewAzureCLICredential(nil)
+	authMethod, err := cmd.Flags().GetString(flagAuthMethod)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	credentials, err := identity.NewCredential(identity.ResolveOptions{
+		AuthMethod: identity.AuthMethod(authMethod),
+	})
 	if err != nil {
-		panic("failed creating azure cli credential")
+		return ctx, nil, fmt.Errorf("authenticating: %w", err)
 	}
-	ctx = identity.WithCredentials(ctx, credentials)
+
+	// WithTokenFileCache acquires nothing up front: a token is acquired, and an ARM
+	// token written to disk for subprocesses to reuse, only when something actually
+	// calls GetToken. Commands that never touch Azure (azd version, azd config, ...)
+	// never pay for the chain walk.
+	ctx = identity.WithCredentials(ctx, identity.WithTokenFileCache(credentials))
 
 	// Create and set the AzCli that will be used for the command
 	azCli := azcli.NewAzCli(azCliArgs)
@@ -155,30 +303,84 @@ func createRootContext(
 		ctx = output.WithFormatter(ctx, formatter)
 	}
 
-	writer := cmd.OutOrStdout()
+	// streamsFromCmd lets tests that wire buffers into cmd.SetIn/SetOut/SetErr still
+	// get TTY/color detection instead of hardcoding os.Stdin/os.Stdout/os.Stderr.
+	streams := streamsFromCmd(cmd)
+	ctx = iostreams.WithIoStreams(ctx, streams)
+	ctx = output.WithWriter(ctx, streams.Out)
 
-	if os.Getenv("NO_COLOR") != "" {
-		writer = colorable.NewNonColorable(writer)
+	debugEnabled, err := cmd.Flags().GetBool(flagDebug)
+	if err != nil {
+		return ctx, nil, err
 	}
 
-	// To support color on windows platforms which don't natively support rendering ANSI codes
-	// we use colorable.NewColorableStdout() which creates a stream that uses the Win32 APIs to
-	// change colors as it interprets the ANSI escape codes in the string it is writing.
-	if writer == os.Stdout {
-		writer = colorable.NewColorableStdout()
-	}
+	outputFormat, _ := cmd.Flags().GetString("output")
+	logger := logging.New(streams, logging.Options{
+		Debug: debugEnabled,
+		JSON:  outputFormat == "json",
+	})
+	ctx = logging.WithLogger(ctx, logger)
 
-	ctx = output.WithWriter(ctx, writer)
+	if headerTransport != nil {
+		// Log header keys only; values may be short-lived auth tokens and must never
+		// reach telemetry or the debug log file.
+		logger.Debug("attaching request headers: %v", httputil.RedactHeaders(headerTransport.Static))
+	}
 
-	isTerminal := cmd.OutOrStdout() == os.Stdout &&
-		cmd.InOrStdin() == os.Stdin && isatty.IsTerminal(os.Stdin.Fd()) &&
-		isatty.IsTerminal(os.Stdout.Fd())
-	console := input.NewConsole(!rootOptions.NoPrompt, isTerminal, input.ConsoleHandles{
-		Stdin:  cmd.InOrStdin(),
-		Stdout: cmd.OutOrStdout(),
-		Stderr: cmd.ErrOrStderr(),
+	console := input.NewConsole(!rootOptions.NoPrompt, streams.IsStdoutTTY(), input.ConsoleHandles{
+		Stdin:  streams.In,
+		Stdout: streams.Out,
+		Stderr: streams.ErrOut,
 	}, formatter)
 	ctx = input.WithConsole(ctx, console)
 
 	return ctx, azdCtx, nil
 }
+
+// headerTransportFromFlags builds the shared http.RoundTripper used by azcli and
+// any future ARM/data-plane clients from the --header and --header-command flags,
+// or returns nil when neither was set.
+func headerTransportFromFlags(cmd *cobra.Command) (*httputil.HeaderTransport, error) {
+	rawHeaders, err := cmd.Flags().GetStringArray(flagHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	headerCommand, err := cmd.Flags().GetString(flagHeaderCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rawHeaders) == 0 && headerCommand == "" {
+		return nil, nil
+	}
+
+	static := http.Header{}
+	for _, raw := range rawHeaders {
+		key, value, err := parseHeaderFlag(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		static.Add(key, value)
+	}
+
+	return &httputil.HeaderTransport{Static: static, Command: headerCommand}, nil
+}
+
+// streamsFromCmd builds an IOStreams from the command's in/out/err handles. When
+// those handles are the process' real stdin/stdout/stderr (the common case outside
+// of tests), it defers to iostreams.System() for TTY detection and color handling;
+// otherwise it wraps the handles as-is with color and TTY detection disabled, which
+// is what tests that inject buffers via cmd.SetIn/SetOut/SetErr expect.
+func streamsFromCmd(cmd *cobra.Command) *iostreams.IOStreams {
+	if cmd.InOrStdin() == os.Stdin && cmd.OutOrStdout() == os.Stdout && cmd.ErrOrStderr() == os.Stderr {
+		return iostreams.System()
+	}
+
+	return &iostreams.IOStreams{
+		In:     cmd.InOrStdin(),
+		Out:    cmd.OutOrStdout(),
+		ErrOut: cmd.ErrOrStderr(),
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeaderFlag_ValidKeyValue(t *testing.T) {
+	key, value, err := parseHeaderFlag("X-Custom=some-value")
+	require.NoError(t, err)
+	require.Equal(t, "X-Custom", key)
+	require.Equal(t, "some-value", value)
+}
+
+func TestParseHeaderFlag_MalformedMissingEquals(t *testing.T) {
+	_, _, err := parseHeaderFlag("X-Custom")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected Key=Value")
+}
+
+// headerFlagsCmd builds a *cobra.Command with the same --header/--header-command
+// persistent flags Build registers, without pulling in the rest of Build's setup.
+func headerFlagsCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.PersistentFlags().StringArray(flagHeader, nil, "")
+	cmd.PersistentFlags().String(flagHeaderCommand, "", "")
+	return cmd
+}
+
+func TestHeaderTransportFromFlags_NilWhenUnset(t *testing.T) {
+	transport, err := headerTransportFromFlags(headerFlagsCmd())
+	require.NoError(t, err)
+	require.Nil(t, transport)
+}
+
+func TestHeaderTransportFromFlags_StaticHeaders(t *testing.T) {
+	cmd := headerFlagsCmd()
+	require.NoError(t, cmd.PersistentFlags().Set(flagHeader, "X-A=1"))
+	require.NoError(t, cmd.PersistentFlags().Set(flagHeader, "X-B=2"))
+
+	transport, err := headerTransportFromFlags(cmd)
+	require.NoError(t, err)
+	require.NotNil(t, transport)
+	require.Equal(t, "1", transport.Static.Get("X-A"))
+	require.Equal(t, "2", transport.Static.Get("X-B"))
+	require.Empty(t, transport.Command)
+}
+
+func TestHeaderTransportFromFlags_HeaderCommand(t *testing.T) {
+	cmd := headerFlagsCmd()
+	require.NoError(t, cmd.PersistentFlags().Set(flagHeaderCommand, "echo hi"))
+
+	transport, err := headerTransportFromFlags(cmd)
+	require.NoError(t, err)
+	require.NotNil(t, transport)
+	require.Equal(t, "echo hi", transport.Command)
+}
+
+func TestHeaderTransportFromFlags_MalformedHeaderValue(t *testing.T) {
+	cmd := headerFlagsCmd()
+	require.NoError(t, cmd.PersistentFlags().Set(flagHeader, "not-a-valid-header"))
+
+	transport, err := headerTransportFromFlags(cmd)
+	require.Error(t, err)
+	require.Nil(t, transport)
+	require.Contains(t, err.Error(), "expected Key=Value")
+}
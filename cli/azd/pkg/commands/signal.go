@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// gracefulShutdownTimeout is how long an action is given to wind down after the first
+// interrupt signal before we consider it unresponsive and terminate the process.
+const gracefulShutdownTimeout = 10 * time.Second
+
+// signalContext returns a copy of parent which is canceled the first time SIGINT or
+// SIGTERM is received. The returned stop function releases resources associated with
+// the context and should be called once the command has finished running.
+//
+// If a second signal arrives after the context has already been canceled, the process
+// is terminated immediately with a non-zero exit code, giving users a way to abort an
+// action that isn't responding to cancellation in a timely manner. If stop has not
+// been called within gracefulShutdownTimeout of the first signal, the process is
+// likewise terminated with a non-zero exit code.
+//
+// A single signal.Notify channel is the sole consumer of SIGINT/SIGTERM here: both
+// the "first signal cancels the context" and "second signal force-exits" behaviors
+// are driven off it directly, rather than racing it against signal.NotifyContext's
+// own internal channel, which has no ordering guarantee relative to this one.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	// Buffered for 2 so a signal delivered before the goroutine below reaches its
+	// second receive (e.g. two Ctrl+C presses in quick succession) is not dropped.
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	// stopped is closed by stop. The second select below waits on it rather than on
+	// ctx.Done, which is already closed once the first signal has canceled ctx.
+	stopped := make(chan struct{})
+	var stopOnce sync.Once
+
+	go func() {
+		defer close(done)
+
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-sigCh:
+			fmt.Fprintln(os.Stderr, "\nazd: received second interrupt, exiting immediately")
+			os.Exit(1)
+		case <-time.After(gracefulShutdownTimeout):
+			fmt.Fprintf(os.Stderr, "\nazd: command did not stop within %s of interrupt, exiting\n",
+				gracefulShutdownTimeout)
+			os.Exit(1)
+		case <-stopped:
+		}
+	}()
+
+	return ctx, func() {
+		stopOnce.Do(func() {
+			signal.Stop(sigCh)
+			close(stopped)
+		})
+		cancel()
+		<-done
+	}
+}
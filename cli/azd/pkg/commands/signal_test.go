@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalContext_CancelsOnFirstSignal(t *testing.T) {
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not canceled after SIGTERM")
+	}
+}
+
+func TestSignalContext_StopReleasesWithoutSignal(t *testing.T) {
+	ctx, stop := signalContext(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop() did not return when no signal was ever received")
+	}
+
+	require.Error(t, ctx.Err())
+}
+
+func TestSignalContext_ParentCancellationStillWorks(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, stop := signalContext(parent)
+	defer stop()
+
+	cancelParent()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not canceled when parent was canceled")
+	}
+}
+
+// Ensure os.Interrupt is also wired up, not just SIGTERM.
+func TestSignalContext_CancelsOnInterrupt(t *testing.T) {
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	proc, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, proc.Signal(os.Interrupt))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not canceled after os.Interrupt")
+	}
+}
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/iostreams"
+	"github.com/azure/azure-dev/cli/azd/pkg/logging"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// flagNoVersionWarning and envVarNoVersionWarning let users opt out of the
+// startup version check entirely, e.g. on air-gapped machines or in CI.
+const (
+	flagNoVersionWarning   = "no-version-warning"
+	envVarNoVersionWarning = "AZD_NO_VERSION_WARNING"
+	latestReleaseEndpoint  = "https://aka.ms/azure-dev/releases/latest"
+	versionCheckInterval   = 24 * time.Hour
+	versionCheckStateFile  = "version-check.json"
+
+	// versionCheckTimeout bounds fetchLatestVersionFromEndpoint independently of the
+	// command's own (potentially very long-lived) context, so a slow or unreachable
+	// endpoint can never hang the command itself, only the non-fatal check.
+	versionCheckTimeout = 2 * time.Second
+)
+
+// versionCheckState is persisted under ~/.azd so the check runs at most once per
+// versionCheckInterval across invocations, rather than on every command.
+type versionCheckState struct {
+	LastChecked time.Time `json:"lastChecked"`
+}
+
+func defaultNoVersionWarning() bool {
+	return os.Getenv(envVarNoVersionWarning) != ""
+}
+
+// checkForNewVersion prints a non-fatal upgrade hint to streams.ErrOut when
+// currentVersion is behind the latest version returned by fetchLatestVersion, and
+// it has been more than versionCheckInterval since the last check recorded in
+// stateFilePath. Otherwise it prints nothing. It never returns an error to the
+// caller, since a failed version check should never block or corrupt a user's command.
+func checkForNewVersion(
+	ctx context.Context,
+	cmd *cobra.Command,
+	currentVersion string,
+	stateFilePath string,
+	fetchLatestVersion func(ctx context.Context) (string, error),
+) {
+	logger := logging.GetLogger(ctx)
+
+	state, err := readVersionCheckState(stateFilePath)
+	if err != nil {
+		logger.Debug("version check: reading state: %v", err)
+	}
+
+	if time.Since(state.LastChecked) < versionCheckInterval {
+		return
+	}
+
+	if err := writeVersionCheckState(stateFilePath, versionCheckState{LastChecked: time.Now()}); err != nil {
+		logger.Debug("version check: writing state: %v", err)
+	}
+
+	latest, err := fetchLatestVersion(ctx)
+	if err != nil {
+		logger.Debug("version check: fetching latest version: %v", err)
+		return
+	}
+
+	if semver.Compare(ensureV(latest), ensureV(currentVersion)) <= 0 {
+		return
+	}
+
+	fmt.Fprintf(
+		cmd.ErrOrStderr(),
+		"\nA new release of azd is available: %s -> %s\nRun `azd version` for upgrade instructions.\n\n",
+		currentVersion,
+		latest,
+	)
+}
+
+// maybeCheckForNewVersion wraps checkForNewVersion with the skip conditions that
+// apply outside of tests: opted out via flag/env, not a TTY, or machine-readable
+// output requested.
+func maybeCheckForNewVersion(ctx context.Context, cmd *cobra.Command, currentVersion string) {
+	noWarning, err := cmd.Flags().GetBool(flagNoVersionWarning)
+	if err != nil || noWarning {
+		return
+	}
+
+	if outputFormat, _ := cmd.Flags().GetString("output"); outputFormat == "json" {
+		return
+	}
+
+	if !iostreams.GetIoStreams(ctx).IsStdoutTTY() {
+		return
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+
+	checkForNewVersion(
+		ctx,
+		cmd,
+		currentVersion,
+		filepath.Join(home, ".azd", versionCheckStateFile),
+		fetchLatestVersionFromEndpoint,
+	)
+}
+
+func fetchLatestVersionFromEndpoint(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseEndpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d fetching latest release", resp.StatusCode)
+	}
+
+	var payload struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decoding latest release: %w", err)
+	}
+
+	return payload.Version, nil
+}
+
+func readVersionCheckState(path string) (versionCheckState, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return versionCheckState{}, nil
+	}
+
+	var state versionCheckState
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return versionCheckState{}, fmt.Errorf("parsing version check state: %w", err)
+	}
+
+	return state, nil
+}
+
+func writeVersionCheckState(path string, state versionCheckState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshalling version check state: %w", err)
+	}
+
+	return os.WriteFile(path, contents, 0600)
+}
+
+func ensureV(version string) string {
+	if version == "" || version[0] == 'v' {
+		return version
+	}
+
+	return "v" + version
+}
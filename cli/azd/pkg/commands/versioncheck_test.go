@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckForNewVersion_PrintsHintWhenBehind(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), versionCheckStateFile)
+	cmd := &cobra.Command{Use: "test"}
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+
+	checkForNewVersion(context.Background(), cmd, "1.0.0", stateFile, func(context.Context) (string, error) {
+		return "2.0.0", nil
+	})
+
+	require.Contains(t, stderr.String(), "1.0.0 -> 2.0.0")
+}
+
+func TestCheckForNewVersion_SilentWhenCurrent(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), versionCheckStateFile)
+	cmd := &cobra.Command{Use: "test"}
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+
+	checkForNewVersion(context.Background(), cmd, "2.0.0", stateFile, func(context.Context) (string, error) {
+		return "2.0.0", nil
+	})
+
+	require.Empty(t, stderr.String())
+}
+
+func TestCheckForNewVersion_SilentOnFetchError(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), versionCheckStateFile)
+	cmd := &cobra.Command{Use: "test"}
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+
+	checkForNewVersion(context.Background(), cmd, "1.0.0", stateFile, func(context.Context) (string, error) {
+		return "", errors.New("network down")
+	})
+
+	require.Empty(t, stderr.String())
+}
+
+func TestCheckForNewVersion_SkipsWithinInterval(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), versionCheckStateFile)
+	require.NoError(t, writeVersionCheckState(stateFile, versionCheckState{LastChecked: time.Now()}))
+
+	cmd := &cobra.Command{Use: "test"}
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+
+	calls := 0
+	checkForNewVersion(context.Background(), cmd, "1.0.0", stateFile, func(context.Context) (string, error) {
+		calls++
+		return "2.0.0", nil
+	})
+
+	require.Equal(t, 0, calls, "fetch must be skipped while within versionCheckInterval")
+	require.Empty(t, stderr.String())
+}
+
+func TestCheckForNewVersion_RunsAfterIntervalElapsed(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), versionCheckStateFile)
+	require.NoError(t, writeVersionCheckState(
+		stateFile,
+		versionCheckState{LastChecked: time.Now().Add(-versionCheckInterval - time.Minute)},
+	))
+
+	cmd := &cobra.Command{Use: "test"}
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+
+	calls := 0
+	checkForNewVersion(context.Background(), cmd, "1.0.0", stateFile, func(context.Context) (string, error) {
+		calls++
+		return "2.0.0", nil
+	})
+
+	require.Equal(t, 1, calls)
+	require.Contains(t, stderr.String(), "1.0.0 -> 2.0.0")
+}
+
+func TestEnsureV(t *testing.T) {
+	require.Equal(t, "v1.2.3", ensureV("1.2.3"))
+	require.Equal(t, "v1.2.3", ensureV("v1.2.3"))
+	require.Equal(t, "", ensureV(""))
+}
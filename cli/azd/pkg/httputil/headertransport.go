@@ -0,0 +1,111 @@
+// Package httputil provides http.RoundTripper decorators shared by azcli and any
+// future ARM or data-plane clients azd builds.
+package httputil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// sensitiveHeaderValue is what RedactHeaders puts in place of every header value,
+// since users attach short-lived auth tokens via --header/--header-command.
+var sensitiveHeaderValue = "***"
+
+// HeaderTransport injects static and dynamically-computed headers into every
+// request, enabling users behind corporate proxies or zero-trust gateways
+// (Cloudflare Access, Zscaler, etc.) to attach auth headers to every Azure API call
+// azd makes without patching the binary.
+type HeaderTransport struct {
+	// Base is the underlying RoundTripper. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Static is merged into every request as-is.
+	Static http.Header
+
+	// Command, if set, is run before every request; its stdout is parsed as
+	// "Key: Value" lines and merged in, taking precedence over Static.
+	Command string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	for key, values := range t.Static {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if t.Command != "" {
+		dynamic, err := runHeaderCommand(req.Context(), t.Command)
+		if err != nil {
+			return nil, fmt.Errorf("running --header-command: %w", err)
+		}
+
+		for key, values := range dynamic {
+			for _, value := range values {
+				req.Header.Set(key, value)
+			}
+		}
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return base.RoundTrip(req)
+}
+
+// runHeaderCommand runs command and parses its stdout as "Key: Value" lines, one
+// header per line, in the same format as HTTP header fields.
+func runHeaderCommand(ctx context.Context, command string) (http.Header, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("executing %q: %w", command, err)
+	}
+
+	headers := http.Header{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid header line %q, expected Key: Value", line)
+		}
+
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parsing header command output: %w", err)
+	}
+
+	return headers, nil
+}
+
+// RedactHeaders returns a copy of headers with every value replaced, suitable for
+// inclusion in telemetry events or debug logs without leaking the auth tokens users
+// attach via --header/--header-command.
+func RedactHeaders(headers http.Header) http.Header {
+	redacted := http.Header{}
+	for key, values := range headers {
+		redacted[key] = make([]string, len(values))
+		for i := range values {
+			redacted[key][i] = sensitiveHeaderValue
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,73 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderTransport_StaticHeaders(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Static")
+	}))
+	defer server.Close()
+
+	transport := &HeaderTransport{Static: http.Header{"X-Static": []string{"value"}}}
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, "value", gotHeader)
+}
+
+func TestHeaderTransport_HeaderCommandOverridesStatic(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	transport := &HeaderTransport{
+		Static:  http.Header{"Authorization": []string{"static-value"}},
+		Command: `echo "Authorization: Bearer dynamic-token"`,
+	}
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, "Bearer dynamic-token", gotHeader)
+}
+
+func TestHeaderTransport_HeaderCommandInvalidOutput(t *testing.T) {
+	transport := &HeaderTransport{Command: `echo "not-a-valid-header-line"`}
+	client := &http.Client{Transport: transport}
+
+	_, err := client.Get("http://example.invalid")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected Key: Value")
+}
+
+func TestHeaderTransport_HeaderCommandFailure(t *testing.T) {
+	transport := &HeaderTransport{Command: "exit 1"}
+	client := &http.Client{Transport: transport}
+
+	_, err := client.Get("http://example.invalid")
+	require.Error(t, err)
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := http.Header{"Authorization": []string{"Bearer secret-token"}}
+
+	redacted := RedactHeaders(headers)
+
+	require.Equal(t, "Bearer secret-token", headers.Get("Authorization"), "original headers must be untouched")
+	require.NotEqual(t, "Bearer secret-token", redacted.Get("Authorization"))
+	require.Equal(t, sensitiveHeaderValue, redacted.Get("Authorization"))
+}
@@ -0,0 +1,28 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+type contextKey string
+
+const credentialsContextKey contextKey = "identity-credentials"
+
+// WithCredentials returns a new context with the given credential attached, to be
+// used as the default for operations against Azure's data and control planes.
+func WithCredentials(ctx context.Context, credential azcore.TokenCredential) context.Context {
+	return context.WithValue(ctx, credentialsContextKey, credential)
+}
+
+// GetCredentials returns the credential previously attached to ctx via WithCredentials,
+// or nil if none has been set.
+func GetCredentials(ctx context.Context) azcore.TokenCredential {
+	credential, ok := ctx.Value(credentialsContextKey).(azcore.TokenCredential)
+	if !ok {
+		return nil
+	}
+
+	return credential
+}
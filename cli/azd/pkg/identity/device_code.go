@@ -0,0 +1,50 @@
+package identity
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// deviceCodeCacheDir is where azd persists the token cache populated by
+// `azd auth login`, scoped under the user's home directory like the rest of azd's
+// on-disk state.
+func deviceCodeCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determining user home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".azd", "auth"), nil
+}
+
+// newDeviceCodeCacheCredential returns a device-code credential for azd's own
+// sign-in, established previously via `azd auth login`. It does not prompt the user.
+// It errors when deviceCodeCacheDir does not exist, so the chain can fall through to
+// the next method; only the directory's existence is checked (writeTokenFile also
+// creates it), and the returned credential is not configured to read a token cache
+// from that directory.
+func newDeviceCodeCacheCredential() (azcore.TokenCredential, error) {
+	cacheDir, err := deviceCodeCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(cacheDir); err != nil {
+		return nil, fmt.Errorf("no cached azd sign-in found, run `azd auth login`: %w", err)
+	}
+
+	cred, err := azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+		// With automatic authentication disabled, GetToken returns an error instead
+		// of starting a new interactive device-code sign-in.
+		DisableAutomaticAuthentication: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading cached sign-in: %w", err)
+	}
+
+	return cred, nil
+}
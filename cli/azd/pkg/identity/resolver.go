@@ -0,0 +1,158 @@
+package identity
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// AuthMethod identifies a single credential type that can be forced via --auth-method
+// or the AZD_AUTH_METHOD environment variable, instead of letting azd probe through
+// the default chain.
+type AuthMethod string
+
+const (
+	// AuthMethodAuto lets azd probe the default credential chain and use whichever
+	// method succeeds first. This is the default.
+	AuthMethodAuto AuthMethod = "auto"
+
+	// AuthMethodEnvironment uses AZURE_CLIENT_ID/AZURE_TENANT_ID and either
+	// AZURE_CLIENT_SECRET or a federated token to authenticate.
+	AuthMethodEnvironment AuthMethod = "environment"
+
+	// AuthMethodWorkloadIdentity uses the token at AZURE_FEDERATED_TOKEN_FILE,
+	// typically set up by AKS workload identity or GitHub Actions OIDC.
+	AuthMethodWorkloadIdentity AuthMethod = "workload-identity"
+
+	// AuthMethodManagedIdentity uses the managed identity assigned to the host
+	// (VM, container app, etc.) azd is running on.
+	AuthMethodManagedIdentity AuthMethod = "managed-identity"
+
+	// AuthMethodDeviceCode uses azd's own cached device-code sign-in, established
+	// via `azd auth login`.
+	AuthMethodDeviceCode AuthMethod = "device-code"
+
+	// AuthMethodAzureCLI shells out to the token cached by `az login`.
+	AuthMethodAzureCLI AuthMethod = "azure-cli"
+)
+
+// EnvVarAuthMethod is the environment variable that can force a single auth method,
+// mirroring the --auth-method flag.
+const EnvVarAuthMethod = "AZD_AUTH_METHOD"
+
+// ResolveOptions controls how NewCredential assembles a credential chain.
+type ResolveOptions struct {
+	// AuthMethod forces a single credential method. If empty or AuthMethodAuto,
+	// the default chain is probed in order instead.
+	AuthMethod AuthMethod
+}
+
+// NewCredential builds the default credential used for operations against Azure's
+// data and control planes. Unless a single method is forced via opts.AuthMethod,
+// it assembles a ChainedTokenCredential that is tried, in order:
+//
+//  1. environment variables (client secret or federated token)
+//  2. workload identity (AZURE_FEDERATED_TOKEN_FILE)
+//  3. managed identity
+//  4. azd's own device-code cache
+//  5. the Azure CLI's cached credential, as a last resort
+//
+// Unlike the credential it replaces, NewCredential never panics: if no method in the
+// chain can be constructed, it returns a descriptive error instead. No token is
+// requested here; a method that is constructed but cannot produce a token fails
+// later, when GetToken is called.
+func NewCredential(opts ResolveOptions) (azcore.TokenCredential, error) {
+	method := opts.AuthMethod
+	if method == "" {
+		if envMethod := os.Getenv(EnvVarAuthMethod); envMethod != "" {
+			method = AuthMethod(envMethod)
+		} else {
+			method = AuthMethodAuto
+		}
+	}
+
+	if method != AuthMethodAuto {
+		return newSingleCredential(method)
+	}
+
+	var creds []azcore.TokenCredential
+	var errs []error
+
+	for _, method := range []AuthMethod{
+		AuthMethodEnvironment,
+		AuthMethodWorkloadIdentity,
+		AuthMethodManagedIdentity,
+		AuthMethodDeviceCode,
+		AuthMethodAzureCLI,
+	} {
+		cred, err := newSingleCredential(method)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		creds = append(creds, cred)
+	}
+
+	if len(creds) == 0 {
+		return nil, fmt.Errorf("no credential method is usable, tried: %w", joinErrors(errs))
+	}
+
+	chain, err := azidentity.NewChainedTokenCredential(creds, nil)
+	if err != nil {
+		return nil, fmt.Errorf("assembling credential chain: %w", err)
+	}
+
+	return chain, nil
+}
+
+func newSingleCredential(method AuthMethod) (azcore.TokenCredential, error) {
+	switch method {
+	case AuthMethodEnvironment:
+		cred, err := azidentity.NewEnvironmentCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("environment credential: %w", err)
+		}
+		return cred, nil
+	case AuthMethodWorkloadIdentity:
+		cred, err := azidentity.NewWorkloadIdentityCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("workload identity credential: %w", err)
+		}
+		return cred, nil
+	case AuthMethodManagedIdentity:
+		cred, err := azidentity.NewManagedIdentityCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("managed identity credential: %w", err)
+		}
+		return cred, nil
+	case AuthMethodDeviceCode:
+		cred, err := newDeviceCodeCacheCredential()
+		if err != nil {
+			return nil, fmt.Errorf("device code credential: %w", err)
+		}
+		return cred, nil
+	case AuthMethodAzureCLI, AuthMethodAuto, "":
+		cred, err := azidentity.NewAzureCLICredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("azure cli credential: %w", err)
+		}
+		return cred, nil
+	default:
+		return nil, fmt.Errorf("unknown auth method %q", method)
+	}
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msg := errs[0].Error()
+	for _, err := range errs[1:] {
+		msg += "; " + err.Error()
+	}
+
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCredential_UnknownAuthMethod(t *testing.T) {
+	_, err := NewCredential(ResolveOptions{AuthMethod: "bogus"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown auth method")
+}
+
+func TestJoinErrors(t *testing.T) {
+	require.Nil(t, joinErrors(nil))
+
+	err := joinErrors([]error{
+		fakeErr("first"),
+		fakeErr("second"),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "first")
+	require.Contains(t, err.Error(), "second")
+}
+
+type fakeErrType string
+
+func (e fakeErrType) Error() string { return string(e) }
+
+func fakeErr(msg string) error { return fakeErrType(msg) }
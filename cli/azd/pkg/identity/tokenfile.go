@@ -0,0 +1,98 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// EnvVarTokenFile is set on subprocesses (bicep, terraform) so they can read a
+// previously acquired token from disk instead of each shelling out to
+// `az account get-access-token`.
+const EnvVarTokenFile = "AZD_ACCESS_TOKEN_FILE"
+
+// armScope is the only scope ever written to the shared token file: bicep and
+// terraform only ever need an ARM token, and writing any other scope's token
+// (Graph, Key Vault, ...) to the same file would silently hand those subprocesses
+// a token for the wrong audience.
+const armScope = "https://management.azure.com/.default"
+
+type cachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresOn time.Time `json:"expiresOn"`
+}
+
+// WithTokenFileCache wraps credential so that every ARM-scope token (see armScope)
+// it successfully acquires is also written to a file under ~/.azd and exported as
+// EnvVarTokenFile, letting subprocesses such as the bicep and terraform providers
+// reuse it instead of each independently shelling out to acquire their own token.
+//
+// Unlike writing the file eagerly in createRootContext, wrapping the credential
+// this way keeps token acquisition lazy: commands that never end up calling
+// GetToken (azd version, azd config, local-only template ops, ...) never pay for
+// a credential chain walk they don't need.
+func WithTokenFileCache(credential azcore.TokenCredential) azcore.TokenCredential {
+	return &tokenFileCachingCredential{TokenCredential: credential}
+}
+
+type tokenFileCachingCredential struct {
+	azcore.TokenCredential
+}
+
+func (c *tokenFileCachingCredential) GetToken(
+	ctx context.Context,
+	options policy.TokenRequestOptions,
+) (azcore.AccessToken, error) {
+	token, err := c.TokenCredential.GetToken(ctx, options)
+	if err != nil {
+		return token, err
+	}
+
+	if !isArmScope(options.Scopes) {
+		return token, nil
+	}
+
+	// Writing the token to disk is a best-effort optimization for subprocesses; a
+	// failure here must never fail the caller's actual token request.
+	if path, writeErr := writeTokenFile(token); writeErr == nil {
+		os.Setenv(EnvVarTokenFile, path)
+	}
+
+	return token, nil
+}
+
+// isArmScope reports whether scopes is exactly the ARM scope, the only audience
+// bicep/terraform subprocesses ever need a token for.
+func isArmScope(scopes []string) bool {
+	return len(scopes) == 1 && scopes[0] == armScope
+}
+
+func writeTokenFile(token azcore.AccessToken) (string, error) {
+	cacheDir, err := deviceCodeCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", fmt.Errorf("creating token cache directory: %w", err)
+	}
+
+	path := filepath.Join(cacheDir, "token.json")
+
+	contents, err := json.Marshal(cachedToken{Token: token.Token, ExpiresOn: token.ExpiresOn})
+	if err != nil {
+		return "", fmt.Errorf("marshalling token: %w", err)
+	}
+
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		return "", fmt.Errorf("writing token file: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCredential struct {
+	token azcore.AccessToken
+	err   error
+	calls int
+}
+
+func (c *stubCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.calls++
+	return c.token, c.err
+}
+
+func TestWithTokenFileCache_WritesFileOnSuccessOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME")) // windows equivalent used by os.UserHomeDir
+
+	stub := &stubCredential{token: azcore.AccessToken{
+		Token:     "fake-token",
+		ExpiresOn: time.Now().Add(time.Hour),
+	}}
+	cached := WithTokenFileCache(stub)
+
+	token, err := cached.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{armScope}})
+	require.NoError(t, err)
+	require.Equal(t, "fake-token", token.Token)
+	require.Equal(t, 1, stub.calls)
+
+	path := os.Getenv(EnvVarTokenFile)
+	require.NotEmpty(t, path)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written cachedToken
+	require.NoError(t, json.Unmarshal(contents, &written))
+	require.Equal(t, "fake-token", written.Token)
+}
+
+func TestWithTokenFileCache_SkipsFileOnFailedGetToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(EnvVarTokenFile, "")
+
+	stub := &stubCredential{err: errors.New("authentication failed")}
+	cached := WithTokenFileCache(stub)
+
+	_, err := cached.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{armScope}})
+	require.Error(t, err)
+	require.Equal(t, 1, stub.calls)
+
+	require.Empty(t, os.Getenv(EnvVarTokenFile))
+	_, statErr := os.Stat(filepath.Join(home, ".azd", "auth", "token.json"))
+	require.True(t, os.IsNotExist(statErr), "token file must not be written when GetToken fails")
+}
+
+func TestWithTokenFileCache_SkipsFileForNonArmScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(EnvVarTokenFile, "")
+
+	stub := &stubCredential{token: azcore.AccessToken{Token: "graph-token", ExpiresOn: time.Now().Add(time.Hour)}}
+	cached := WithTokenFileCache(stub)
+
+	_, err := cached.GetToken(
+		context.Background(),
+		policy.TokenRequestOptions{Scopes: []string{"https://graph.microsoft.com/.default"}},
+	)
+	require.NoError(t, err)
+
+	require.Empty(t, os.Getenv(EnvVarTokenFile))
+	_, statErr := os.Stat(filepath.Join(home, ".azd", "auth", "token.json"))
+	require.True(t, os.IsNotExist(statErr), "token file must not be written for a non-ARM scope")
+}
+
+func TestWithTokenFileCache_DoesNotAcquireUntilCalled(t *testing.T) {
+	stub := &stubCredential{}
+	_ = WithTokenFileCache(stub)
+
+	require.Equal(t, 0, stub.calls, "wrapping a credential must not eagerly call GetToken")
+}
+
+func TestWriteTokenFile_Path(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	path, err := writeTokenFile(azcore.AccessToken{Token: "t", ExpiresOn: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, ".azd", "auth", "token.json"), path)
+}
@@ -0,0 +1,57 @@
+package iostreams
+
+import "fmt"
+
+// ColorScheme provides consistent success/warning/error styling for command
+// output, no-ops when color is disabled so callers never need to branch on
+// ColorEnabled themselves.
+type ColorScheme struct {
+	enabled bool
+}
+
+func newColorScheme(enabled bool) *ColorScheme {
+	return &ColorScheme{enabled: enabled}
+}
+
+func (c *ColorScheme) colorize(code, s string) string {
+	if !c.enabled {
+		return s
+	}
+
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, s)
+}
+
+// SuccessIcon returns a colorized checkmark, suitable as a line prefix.
+func (c *ColorScheme) SuccessIcon() string {
+	return c.Green("(✓) Done:")
+}
+
+// WarningIcon returns a colorized warning prefix.
+func (c *ColorScheme) WarningIcon() string {
+	return c.Yellow("(!) Warning:")
+}
+
+// ErrorIcon returns a colorized error prefix.
+func (c *ColorScheme) ErrorIcon() string {
+	return c.Red("(x) Error:")
+}
+
+// Green colorizes s as green.
+func (c *ColorScheme) Green(s string) string {
+	return c.colorize("32", s)
+}
+
+// Yellow colorizes s as yellow.
+func (c *ColorScheme) Yellow(s string) string {
+	return c.colorize("33", s)
+}
+
+// Red colorizes s as red.
+func (c *ColorScheme) Red(s string) string {
+	return c.colorize("31", s)
+}
+
+// Cyan colorizes s as cyan.
+func (c *ColorScheme) Cyan(s string) string {
+	return c.colorize("36", s)
+}
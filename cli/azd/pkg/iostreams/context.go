@@ -0,0 +1,23 @@
+package iostreams
+
+import "context"
+
+type contextKey string
+
+const iostreamsContextKey contextKey = "iostreams"
+
+// WithIoStreams returns a new context with the given IOStreams attached.
+func WithIoStreams(ctx context.Context, streams *IOStreams) context.Context {
+	return context.WithValue(ctx, iostreamsContextKey, streams)
+}
+
+// GetIoStreams returns the IOStreams previously attached to ctx via WithIoStreams,
+// or the system streams if none has been set.
+func GetIoStreams(ctx context.Context) *IOStreams {
+	streams, ok := ctx.Value(iostreamsContextKey).(*IOStreams)
+	if !ok {
+		return System()
+	}
+
+	return streams
+}
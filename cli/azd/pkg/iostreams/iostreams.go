@@ -0,0 +1,133 @@
+// Package iostreams centralizes the TTY detection, color handling, and stream
+// plumbing that used to be scattered across createRootContext, so tests can inject
+// buffers instead of racing against the real os.Stdin/os.Stdout/os.Stderr.
+package iostreams
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-colorable"
+	"github.com/mattn/go-isatty"
+)
+
+// IOStreams owns the input, output and error streams for a single command
+// invocation, along with everything needed to decide how to render to them.
+type IOStreams struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+
+	// stdinTTY, stdoutTTY and stderrTTY record whether In/Out/ErrOut are connected to
+	// a terminal, as determined when the IOStreams was constructed. Out and ErrOut
+	// are frequently redirected independently of one another (e.g. `azd up
+	// > out.log`), so callers must not assume one implies the other.
+	stdinTTY  bool
+	stdoutTTY bool
+	stderrTTY bool
+
+	// colorEnabled and errColorEnabled are nil until first queried, at which point
+	// they are resolved and cached.
+	colorEnabled    *bool
+	errColorEnabled *bool
+}
+
+// System returns the IOStreams wrapping the process' real stdin/stdout/stderr,
+// applying NO_COLOR and Windows ANSI-compatibility handling where needed.
+func System() *IOStreams {
+	stdout := io.Writer(os.Stdout)
+	if os.Getenv("NO_COLOR") != "" {
+		stdout = colorable.NewNonColorable(stdout)
+	} else {
+		// On Windows this wraps stdout to translate ANSI escape codes via the Win32
+		// console APIs; on other platforms it is a no-op pass-through.
+		stdout = colorable.NewColorableStdout()
+	}
+
+	return &IOStreams{
+		In:        os.Stdin,
+		Out:       stdout,
+		ErrOut:    os.Stderr,
+		stdinTTY:  isatty.IsTerminal(os.Stdin.Fd()),
+		stdoutTTY: isatty.IsTerminal(os.Stdout.Fd()),
+		stderrTTY: isatty.IsTerminal(os.Stderr.Fd()),
+	}
+}
+
+// Test returns an IOStreams backed by in-memory buffers, along with the buffers
+// themselves, for use in tests that need to inject input or assert on output.
+func Test() (streams *IOStreams, in *TestBuffer, out *TestBuffer, errOut *TestBuffer) {
+	in, out, errOut = &TestBuffer{}, &TestBuffer{}, &TestBuffer{}
+	streams = &IOStreams{In: in, Out: out, ErrOut: errOut}
+	return streams, in, out, errOut
+}
+
+// IsStdinTTY reports whether In is connected to a terminal.
+func (s *IOStreams) IsStdinTTY() bool {
+	return s.stdinTTY
+}
+
+// IsStdoutTTY reports whether Out is connected to a terminal.
+func (s *IOStreams) IsStdoutTTY() bool {
+	return s.stdoutTTY
+}
+
+// IsStderrTTY reports whether ErrOut is connected to a terminal. This is tracked
+// separately from IsStdoutTTY since the two streams are commonly redirected
+// independently of one another (e.g. `azd up > out.log`).
+func (s *IOStreams) IsStderrTTY() bool {
+	return s.stderrTTY
+}
+
+// ColorEnabled reports whether Out should be written to with ANSI color codes:
+// true when Out is a TTY and NO_COLOR is not set.
+func (s *IOStreams) ColorEnabled() bool {
+	if s.colorEnabled != nil {
+		return *s.colorEnabled
+	}
+
+	enabled := s.stdoutTTY && os.Getenv("NO_COLOR") == ""
+	s.colorEnabled = &enabled
+	return enabled
+}
+
+// ErrColorEnabled reports whether ErrOut should be written to with ANSI color
+// codes: true when ErrOut is a TTY and NO_COLOR is not set. Callers writing to
+// ErrOut (such as pkg/logging) must gate on this instead of ColorEnabled, which
+// reflects Out's TTY-ness, not ErrOut's.
+func (s *IOStreams) ErrColorEnabled() bool {
+	if s.errColorEnabled != nil {
+		return *s.errColorEnabled
+	}
+
+	enabled := s.stderrTTY && os.Getenv("NO_COLOR") == ""
+	s.errColorEnabled = &enabled
+	return enabled
+}
+
+// ColorScheme returns the ColorScheme to use when writing to Out.
+func (s *IOStreams) ColorScheme() *ColorScheme {
+	return newColorScheme(s.ColorEnabled())
+}
+
+// ErrColorScheme returns the ColorScheme to use when writing to ErrOut.
+func (s *IOStreams) ErrColorScheme() *ColorScheme {
+	return newColorScheme(s.ErrColorEnabled())
+}
+
+// TerminalWidth returns the width of the terminal attached to Out, or a
+// reasonable default if it cannot be determined (Out is not a terminal, or the
+// platform does not support the ioctl used to query it).
+func (s *IOStreams) TerminalWidth() int {
+	const defaultWidth = 80
+
+	if !s.stdoutTTY {
+		return defaultWidth
+	}
+
+	if width, _, err := terminalSize(os.Stdout.Fd()); err == nil && width > 0 {
+		return width
+	}
+
+	return defaultWidth
+}
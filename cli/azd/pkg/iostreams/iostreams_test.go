@@ -0,0 +1,49 @@
+package iostreams
+
+import "testing"
+
+func TestColorEnabled_IndependentOfErrColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	streams := &IOStreams{stdoutTTY: true, stderrTTY: false}
+
+	if !streams.ColorEnabled() {
+		t.Error("expected ColorEnabled to be true when stdout is a TTY")
+	}
+	if streams.ErrColorEnabled() {
+		t.Error("expected ErrColorEnabled to be false when stderr is not a TTY, regardless of stdout")
+	}
+}
+
+func TestErrColorEnabled_TrueWhenStderrIsTTYEvenIfStdoutIsNot(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	streams := &IOStreams{stdoutTTY: false, stderrTTY: true}
+
+	if streams.ColorEnabled() {
+		t.Error("expected ColorEnabled to be false when stdout is not a TTY")
+	}
+	if !streams.ErrColorEnabled() {
+		t.Error("expected ErrColorEnabled to be true when stderr is a TTY, regardless of stdout")
+	}
+}
+
+func TestNoColorEnvDisablesBoth(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	streams := &IOStreams{stdoutTTY: true, stderrTTY: true}
+
+	if streams.ColorEnabled() {
+		t.Error("expected ColorEnabled to be false when NO_COLOR is set")
+	}
+	if streams.ErrColorEnabled() {
+		t.Error("expected ErrColorEnabled to be false when NO_COLOR is set")
+	}
+}
+
+func TestIsStderrTTY(t *testing.T) {
+	streams := &IOStreams{stderrTTY: true}
+	if !streams.IsStderrTTY() {
+		t.Error("expected IsStderrTTY to reflect the constructed value")
+	}
+}
@@ -0,0 +1,9 @@
+package iostreams
+
+import "golang.org/x/term"
+
+// terminalSize returns the width and height, in characters, of the terminal
+// attached to the given file descriptor.
+func terminalSize(fd uintptr) (width int, height int, err error) {
+	return term.GetSize(int(fd))
+}
@@ -0,0 +1,40 @@
+package iostreams
+
+import (
+	"bytes"
+	"sync"
+)
+
+// TestBuffer is a concurrency-safe bytes.Buffer, used to back the In/Out/ErrOut
+// streams returned by Test so assertions can run safely alongside command output
+// written from other goroutines.
+type TestBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *TestBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Read(p)
+}
+
+func (b *TestBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the buffer's contents as a string.
+func (b *TestBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Bytes returns the buffer's contents.
+func (b *TestBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
@@ -0,0 +1,24 @@
+package logging
+
+import "context"
+
+type contextKey string
+
+const loggerContextKey contextKey = "logger"
+
+// WithLogger returns a new context with the given Logger attached.
+func WithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// GetLogger returns the Logger previously attached to ctx via WithLogger. If none
+// has been set, it returns a new file-backed Logger over the system streams with
+// default Options (see defaultLogger), so callers never need a nil check.
+func GetLogger(ctx context.Context) *Logger {
+	logger, ok := ctx.Value(loggerContextKey).(*Logger)
+	if !ok {
+		return defaultLogger()
+	}
+
+	return logger
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// openDebugLogFile opens (creating if necessary) the rotating debug log for this
+// process invocation, under ~/.azd/logs/azd-<timestamp>.log. Each invocation of azd
+// gets its own file; pruning old files is left to the user, mirroring how azd
+// already leaves environment state under ~/.azd for the user to manage.
+func openDebugLogFile() (*os.File, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("determining user home directory: %w", err)
+	}
+
+	logDir := filepath.Join(home, ".azd", "logs")
+	if err := os.MkdirAll(logDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+
+	name := fmt.Sprintf("azd-%s.log", time.Now().UTC().Format("20060102T150405Z"))
+	file, err := os.OpenFile(filepath.Join(logDir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+
+	return file, nil
+}
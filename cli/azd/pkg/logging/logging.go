@@ -0,0 +1,155 @@
+// Package logging provides a leveled logger for azd's own diagnostic output,
+// distinct from the user-facing messages written through input.Console. It always
+// writes to a rotating file under ~/.azd/logs so that a bug report can attach a
+// real trace, regardless of whether --debug was passed or the session is a TTY.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/iostreams"
+	"github.com/mattn/go-colorable"
+)
+
+// Level is the severity of a single log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Logger writes leveled diagnostic output to a human or JSON sink, and always
+// mirrors every entry to the on-disk debug log returned by openDebugLogFile.
+type Logger struct {
+	minLevel  Level
+	human     io.Writer
+	jsonMode  bool
+	colorized bool
+	fileSink  io.Writer
+}
+
+// Options configures a Logger.
+type Options struct {
+	// Debug enables Debug-level output on the human/JSON sink. Debug-level entries
+	// are always written to the file sink regardless of this setting.
+	Debug bool
+
+	// JSON selects newline-delimited JSON output instead of colorized text, for use
+	// with --output json.
+	JSON bool
+}
+
+// New constructs a Logger that writes human or JSON output to streams.ErrOut, and
+// mirrors every entry, including debug entries, to the rotating file sink.
+func New(streams *iostreams.IOStreams, opts Options) *Logger {
+	minLevel := LevelInfo
+	if opts.Debug {
+		minLevel = LevelDebug
+	}
+
+	// Logger writes to streams.ErrOut, so it must gate colorization on ErrOut's own
+	// TTY-ness, not Out's: the two are commonly redirected independently of one
+	// another (e.g. `azd up > out.log`).
+	errOut := streams.ErrOut
+	if streams.ErrColorEnabled() {
+		errOut = colorable.NewColorable(asFile(errOut))
+	}
+
+	fileSink, err := openDebugLogFile()
+	if err != nil {
+		// Logging is diagnostic, not load-bearing: fall back to discarding rather
+		// than failing the command over a log file we couldn't open.
+		fileSink = io.Discard
+	}
+
+	return &Logger{
+		minLevel:  minLevel,
+		human:     errOut,
+		jsonMode:  opts.JSON,
+		colorized: streams.ErrColorEnabled(),
+		fileSink:  fileSink,
+	}
+}
+
+// defaultLogger is used when a command path is reached without ever calling New,
+// e.g. in code invoked outside of a Build-constructed command. It writes to the
+// real process streams and the on-disk file sink exactly like a wired-up Logger;
+// nothing about this fallback is silent.
+func defaultLogger() *Logger {
+	return New(iostreams.System(), Options{})
+}
+
+func asFile(w io.Writer) *os.File {
+	if f, ok := w.(*os.File); ok {
+		return f
+	}
+
+	return os.Stderr
+}
+
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	fmt.Fprintf(l.fileSink, "%s [%s] %s\n", timestamp, level, msg)
+
+	if level < l.minLevel {
+		return
+	}
+
+	if l.jsonMode {
+		fmt.Fprintf(l.human, `{"time":%q,"level":%q,"message":%q}`+"\n", timestamp, level, msg)
+		return
+	}
+
+	fmt.Fprintf(l.human, "%s: %s\n", levelLabel(level, l.colorized), msg)
+}
+
+func levelLabel(level Level, colorized bool) string {
+	if !colorized {
+		return level.String()
+	}
+
+	code := "36" // cyan for debug/info
+	switch level {
+	case LevelWarn:
+		code = "33"
+	case LevelError:
+		code = "31"
+	}
+
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, level.String())
+}
+
+// Debug logs a debug-level entry.
+func (l *Logger) Debug(format string, args ...interface{}) { l.log(LevelDebug, format, args...) }
+
+// Info logs an info-level entry.
+func (l *Logger) Info(format string, args ...interface{}) { l.log(LevelInfo, format, args...) }
+
+// Warn logs a warn-level entry.
+func (l *Logger) Warn(format string, args ...interface{}) { l.log(LevelWarn, format, args...) }
+
+// Error logs an error-level entry.
+func (l *Logger) Error(format string, args ...interface{}) { l.log(LevelError, format, args...) }
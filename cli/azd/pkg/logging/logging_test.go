@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/iostreams"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_NoColorWhenStderrIsNotTTY(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("HOME", t.TempDir())
+
+	streams, _, _, errOut := iostreams.Test()
+	// iostreams.Test() reports neither stream as a TTY, so ErrColorEnabled is false
+	// and the logger must write plain text to ErrOut.
+	_ = errOut
+
+	logger := New(streams, Options{})
+	logger.Info("hello")
+
+	require.Contains(t, errOut.String(), "hello")
+	require.NotContains(t, errOut.String(), "\x1b[", "expected no ANSI codes when neither stream is a TTY")
+}
+
+func TestLog_RespectsMinLevel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	streams, _, _, errOut := iostreams.Test()
+	logger := New(streams, Options{Debug: false})
+
+	logger.Debug("should not appear")
+	logger.Info("should appear")
+
+	out := errOut.String()
+	require.False(t, strings.Contains(out, "should not appear"))
+	require.True(t, strings.Contains(out, "should appear"))
+}
+
+func TestLog_JSONMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	streams, _, _, errOut := iostreams.Test()
+	logger := New(streams, Options{JSON: true})
+
+	logger.Info("hello %s", "world")
+
+	require.Contains(t, errOut.String(), `"message":"hello world"`)
+}